@@ -0,0 +1,109 @@
+package statsd
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeConn is a minimal net.Conn that records writes instead of hitting
+// the network, so buffered-flush tests don't require a live socket. Its
+// writes are read concurrently by test goroutines (e.g. polling for a
+// background flushLoop write), so access is guarded by mu.
+type fakeConn struct {
+	net.Conn
+
+	mu     sync.Mutex
+	writes [][]byte
+}
+
+func (f *fakeConn) Write(b []byte) (int, error) {
+	cp := append([]byte(nil), b...)
+	f.mu.Lock()
+	f.writes = append(f.writes, cp)
+	f.mu.Unlock()
+	return len(b), nil
+}
+
+// Writes returns a snapshot of the writes recorded so far.
+func (f *fakeConn) Writes() [][]byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	writes := make([][]byte, len(f.writes))
+	copy(writes, f.writes)
+	return writes
+}
+
+func newTestBufferedClient(mtu int) (*BufferedClient, *fakeConn) {
+	conn := &fakeConn{}
+	sink := &udpSink{nc: conn}
+	client := &Client{sink: sink}
+	bc := &BufferedClient{Client: client, sink: sink, mtu: mtu}
+	sink.writeFunc = bc.bufferWrite
+	return bc, conn
+}
+
+func TestBufferedWriteJoinsWithNewline(t *testing.T) {
+	bc, _ := newTestBufferedClient(defaultMTU)
+	bc.bufferWrite([]byte("stat.a:1|c"))
+	bc.bufferWrite([]byte("stat.b:2|c"))
+
+	if got, want := string(bc.buf), "stat.a:1|c\nstat.b:2|c"; got != want {
+		t.Errorf("buffer = %q, want %q", got, want)
+	}
+}
+
+func TestBufferedWriteFlushesOnMTU(t *testing.T) {
+	bc, conn := newTestBufferedClient(10)
+	bc.bufferWrite([]byte("0123456789"))
+	bc.bufferWrite([]byte("x"))
+
+	if writes := conn.Writes(); len(writes) != 1 || string(writes[0]) != "0123456789" {
+		t.Errorf("expected a flush of the first stat before the second was buffered, got %v", writes)
+	}
+	if got, want := string(bc.buf), "x"; got != want {
+		t.Errorf("buffer = %q, want %q", got, want)
+	}
+}
+
+func TestFlushSendsAndClearsBuffer(t *testing.T) {
+	bc, conn := newTestBufferedClient(defaultMTU)
+	bc.bufferWrite([]byte("stat.a:1|c"))
+	bc.Flush()
+
+	if writes := conn.Writes(); len(writes) != 1 || string(writes[0]) != "stat.a:1|c" {
+		t.Errorf("expected flush to write the buffered stat, got %v", writes)
+	}
+	if len(bc.buf) != 0 {
+		t.Errorf("expected buffer to be empty after flush, got %q", bc.buf)
+	}
+}
+
+func TestNewBufferedClientDefaultsNonPositiveFlushInterval(t *testing.T) {
+	bc, err := NewBufferedClient(testAppname, "statsd.internal", defaultMTU, 0)
+	if err != nil {
+		t.Fatalf("NewBufferedClient returned an error: %v", err)
+	}
+	defer bc.Close()
+
+	if bc.flushInterval != defaultFlushInterval {
+		t.Errorf("flushInterval = %v, want defaultFlushInterval %v", bc.flushInterval, defaultFlushInterval)
+	}
+}
+
+func TestBufferedClientFlushLoop(t *testing.T) {
+	bc, conn := newTestBufferedClient(defaultMTU)
+	bc.flushInterval = 10 * time.Millisecond
+	bc.stopCh = make(chan struct{})
+	bc.stoppedCh = make(chan struct{})
+	go bc.flushLoop()
+	defer close(bc.stopCh)
+
+	bc.bufferWrite([]byte("stat.a:1|c"))
+	time.Sleep(30 * time.Millisecond)
+
+	if len(conn.Writes()) == 0 {
+		t.Error("expected periodic flush to have sent the buffered stat")
+	}
+}
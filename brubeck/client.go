@@ -1,7 +1,10 @@
-// Package statsd provides an extremely simple statsd client that can
-// be used to send metrics to a statsd server.
+// Package statsd provides a client for sending metrics through a pluggable
+// Sink, e.g. statsd/DogStatsD over UDP (the default), Graphite over TCP, or
+// an in-memory sink for tests. Client itself only handles naming,
+// namespacing, and tag policy; see the Sink interface for the emission
+// backends available.
 //
-// See http://github.com/etsy/statsd for details.
+// See http://github.com/etsy/statsd for details on the statsd wire format.
 //
 // Messages take the form of "<stat_name>:<magnitude>|<unit>
 //
@@ -10,42 +13,144 @@
 package statsd
 
 import (
-	"fmt"
 	"math"
 	"math/rand"
-	"net"
-	"strconv"
 	"strings"
-	"time"
 )
 
-const statsdPort = 8125
 const namespacePrefix = "brubeck.stats_d"
 const timerNamespacePrefix = "brubeck.stats_d.timers"
-const timeFormat = "%.2f|ms"
-const countFormat = "%d|c"
+const gaugeNamespacePrefix = "brubeck.stats_d.gauges"
+const setNamespacePrefix = "brubeck.stats_d.sets"
+const histogramNamespacePrefix = "brubeck.stats_d.histograms"
+const meterNamespacePrefix = "brubeck.stats_d.meters"
 
-// Client object that users interact with to send stats to Statsd.
+// statUnit identifies which statsd metric type a stat is, which in turn
+// selects the namespace prefix the stat is nested under.
+type statUnit int
+
+const (
+	unitCount statUnit = iota
+	unitTime
+	unitGauge
+	unitSet
+	unitHistogram
+	unitMeter
+)
+
+// namespacePrefix returns the namespace a stat of this unit is nested
+// under, e.g. "brubeck.stats_d.timers" for unitTime.
+func (u statUnit) namespacePrefix() string {
+	switch u {
+	case unitTime:
+		return timerNamespacePrefix
+	case unitGauge:
+		return gaugeNamespacePrefix
+	case unitSet:
+		return setNamespacePrefix
+	case unitHistogram:
+		return histogramNamespacePrefix
+	case unitMeter:
+		return meterNamespacePrefix
+	default:
+		return namespacePrefix
+	}
+}
+
+// Tag is a single key/value dimension attached to a metric emission using
+// the DogStatsD tag extension. It has no effect unless the client was
+// constructed with WithTags.
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// Client object that users interact with to send stats to Statsd. Client is
+// a thin wrapper over a Sink: it owns naming/namespacing/tag policy and
+// delegates the actual emission to whatever backend the Sink implements
+// (UDP statsd by default; see NewMemorySink, NewFanoutSink, and
+// NewGraphiteSink for alternatives).
 type Client struct {
-	prefix   string // must be different across apps
-	host     string // the statsd server to send to
-	disabled bool   // if true will not send stats. Useful for test/stage/dev
-	nc       net.Conn
+	prefix       string // must be different across apps
+	host         string // the statsd server to send to
+	disabled     bool   // if true will not send stats. Useful for test/stage/dev
+	sink         Sink
+	tagsEnabled  bool  // if true, tags are appended using the DogStatsD |#k:v,... extension
+	baseTags     []Tag // tags merged into every emission, e.g. node/datacenter/service
+	errorHandler ErrorHandler
+}
+
+// Option configures optional Client behavior. See WithTags and WithBaseTags.
+type Option func(*Client)
+
+// WithTags enables the DogStatsD tag extension, appending "|#k1:v1,k2:v2"
+// to emitted stats. Leave it unset for vanilla Brubeck/etsy statsd servers,
+// which don't understand the extension.
+func WithTags() Option {
+	return func(c *Client) {
+		c.tagsEnabled = true
+	}
+}
+
+// WithBaseTags registers tags that are merged into every emission from this
+// client, e.g. node, datacenter, or service. Has no effect unless WithTags
+// is also passed.
+func WithBaseTags(tags ...Tag) Option {
+	return func(c *Client) {
+		c.baseTags = append(c.baseTags, tags...)
+	}
+}
+
+// WithSink attaches a custom Sink to the client instead of dialing the
+// default UDP statsd socket, e.g. a MemorySink for tests, a FanoutSink to
+// emit to several backends at once, or a GraphiteSink.
+func WithSink(sink Sink) Option {
+	return func(c *Client) {
+		c.sink = sink
+	}
 }
 
 // NewClient creates a new graphite client. Not intended to be used
 // more than once per application; call only from from your main
 // goroutine.
-func NewClient(prefix string, host string, disabled bool) *Client {
+func NewClient(prefix string, host string, disabled bool, opts ...Option) (*Client, error) {
 	client := &Client{
 		host:     host,
 		disabled: disabled,
 		prefix:   prefix,
 	}
-	if !disabled {
-		client.newUDPSocket()
+	for _, opt := range opts {
+		opt(client)
+	}
+	if !disabled && client.sink == nil {
+		sink, err := newUDPSink(host, client.errorHandler)
+		if err != nil {
+			return nil, err
+		}
+		client.sink = sink
+	}
+	return client, nil
+}
+
+// namespacedStat builds the fully-qualified stat name a Sink receives,
+// e.g. "brubeck.stats_d.timers.<prefix>.<stat>".
+func (c *Client) namespacedStat(stat string, unit statUnit) string {
+	return strings.Join([]string{unit.namespacePrefix(), c.prefix, stat}, ".")
+}
+
+// mergeTags merges the client's base tags with per-call tags, returning nil
+// (no tags sent) unless the client was built with WithTags.
+func (c *Client) mergeTags(tags []Tag) []Tag {
+	if !c.tagsEnabled {
+		return nil
+	}
+	if len(c.baseTags) == 0 {
+		return tags
 	}
-	return client
+	all := make([]Tag, 0, len(c.baseTags)+len(tags))
+	all = append(all, c.baseTags...)
+	all = append(all, tags...)
+	return all
 }
 
 // If we're sending a sampled value to statsd, we need to increase
@@ -67,49 +172,103 @@ func (c *Client) sampleCounts(count int64, sampleRate float32) int64 {
 	return int64(math.Ceil(float64(float32(count) / sampleRate)))
 }
 
-func (c *Client) newUDPSocket() {
-	hostname := c.host + ":" + strconv.Itoa(statsdPort)
-	conn, _ := net.DialTimeout("udp", hostname, 5*time.Second)
-	c.nc = conn
+// Incr increments a counter metric by one.
+func (c *Client) Incr(stat string) {
+	c.IncrBatch(stat, 1)
 }
 
-func (c *Client) formatStat(stat string, format string, value interface{}, timed bool) string {
-	var strFormat string
-	if timed {
-		strFormat = fmt.Sprintf("%s:%s", strings.Join([]string{timerNamespacePrefix, c.prefix, stat}, "."), format)
-	} else {
-		strFormat = fmt.Sprintf("%s:%s", strings.Join([]string{namespacePrefix, c.prefix, stat}, "."), format)
+// Decr decrements a counter metric by one.
+func (c *Client) Decr(stat string) {
+	c.DecrBatch(stat, 1)
+}
+
+// IncrBatch increments a counter metric by count.
+func (c *Client) IncrBatch(stat string, count int64) {
+	if c.disabled {
+		return
 	}
-	return fmt.Sprintf(strFormat, value)
+	c.sink.EmitCounter(c.namespacedStat(stat, unitCount), count, c.mergeTags(nil))
 }
 
-func (c *Client) send(stat string, format string, value interface{}, timed bool) {
+// DecrBatch decrements a counter metric by count.
+func (c *Client) DecrBatch(stat string, count int64) {
 	if c.disabled {
 		return
 	}
+	c.sink.EmitCounter(c.namespacedStat(stat, unitCount), -count, c.mergeTags(nil))
+}
 
-	fstat := c.formatStat(stat, format, value, timed)
-	c.nc.Write([]byte(fstat))
+// IncrTagged increments a counter metric by count with the given tags
+// attached, e.g. client.IncrTagged("hits", 1, statsd.Tag{"route", "/login"}).
+// Tags are only sent over the wire if the client was built with WithTags.
+func (c *Client) IncrTagged(stat string, count int64, tags ...Tag) {
+	if c.disabled {
+		return
+	}
+	c.sink.EmitCounter(c.namespacedStat(stat, unitCount), count, c.mergeTags(tags))
 }
 
-// Incr increments a counter metric by one.
-func (c *Client) Incr(stat string) {
-	c.IncrBatch(stat, 1)
+// DecrTagged decrements a counter metric by count with the given tags
+// attached. Tags are only sent over the wire if the client was built with
+// WithTags.
+func (c *Client) DecrTagged(stat string, count int64, tags ...Tag) {
+	if c.disabled {
+		return
+	}
+	c.sink.EmitCounter(c.namespacedStat(stat, unitCount), -count, c.mergeTags(tags))
 }
 
-// Decr decrements a counter metric by one.
-func (c *Client) Decr(stat string) {
-	c.DecrBatch(stat, 1)
+// Gauge sets a gauge metric to an absolute value.
+func (c *Client) Gauge(stat string, value float64) {
+	if c.disabled {
+		return
+	}
+	c.sink.EmitGauge(c.namespacedStat(stat, unitGauge), value, c.mergeTags(nil))
 }
 
-// Incr increments a counter metric by count.
-func (c *Client) IncrBatch(stat string, count int64) {
-	c.send(stat, countFormat, count, false)
+// GaugeTagged sets a gauge metric to an absolute value with the given tags
+// attached. Tags are only sent over the wire if the client was built with
+// WithTags.
+func (c *Client) GaugeTagged(stat string, value float64, tags ...Tag) {
+	if c.disabled {
+		return
+	}
+	c.sink.EmitGauge(c.namespacedStat(stat, unitGauge), value, c.mergeTags(tags))
 }
 
-// Decr decrements a counter metric by count.
-func (c *Client) DecrBatch(stat string, count int64) {
-	c.send(stat, countFormat, -count, false)
+// GaugeDelta adjusts a gauge metric relative to its current value, e.g.
+// GaugeDelta(stat, -5) subtracts 5 from the gauge server-side.
+func (c *Client) GaugeDelta(stat string, delta float64) {
+	if c.disabled {
+		return
+	}
+	c.sink.EmitGaugeDelta(c.namespacedStat(stat, unitGauge), delta, c.mergeTags(nil))
+}
+
+// Set records a value as a member of a set, useful for counting unique
+// occurrences of a stat (e.g. unique user IDs) over the flush interval.
+func (c *Client) Set(stat string, value string) {
+	if c.disabled {
+		return
+	}
+	c.sink.EmitSet(c.namespacedStat(stat, unitSet), value, c.mergeTags(nil))
+}
+
+// Histogram sends a value to be tracked as a histogram (min/max/mean/
+// percentiles computed server-side).
+func (c *Client) Histogram(stat string, value float64) {
+	if c.disabled {
+		return
+	}
+	c.sink.EmitHistogram(c.namespacedStat(stat, unitHistogram), value, c.mergeTags(nil))
+}
+
+// Meter sends a value to be tracked as a meter, i.e. a rate over time.
+func (c *Client) Meter(stat string, value float64) {
+	if c.disabled {
+		return
+	}
+	c.sink.EmitMeter(c.namespacedStat(stat, unitMeter), value, c.mergeTags(nil))
 }
 
 // sampled returns True if the stat should be sent, otherwise False.
@@ -135,12 +294,25 @@ func (c *Client) DecrSampled(stat string, count int64, sampleRate float32) {
 
 // Time sends millisecond timing to statsd
 func (c *Client) Time(stat string, time float32) {
-	c.send(stat, timeFormat, time, true)
+	if c.disabled {
+		return
+	}
+	c.sink.EmitTimer(c.namespacedStat(stat, unitTime), time, c.mergeTags(nil))
+}
+
+// TimeTagged sends millisecond timing to statsd with the given tags
+// attached. Tags are only sent over the wire if the client was built with
+// WithTags.
+func (c *Client) TimeTagged(stat string, time float32, tags ...Tag) {
+	if c.disabled {
+		return
+	}
+	c.sink.EmitTimer(c.namespacedStat(stat, unitTime), time, c.mergeTags(tags))
 }
 
 // SampleTime sends sampled millisecond timing to statsd server
 func (c *Client) SampleTime(stat string, time float32, sampleRate float32) {
 	if c.sampled(sampleRate) {
-		c.send(stat, timeFormat, time, true)
+		c.Time(stat, time)
 	}
 }
@@ -2,21 +2,22 @@ package statsd
 
 import (
 	"math"
-	"strings"
 	"testing"
 )
 
 const testAppname = "appname"
 
-var formatTests = []struct {
-	stat   string
-	format string
-	value  interface{}
-	timed  bool
-	out    string
+var namespacedStatTests = []struct {
+	stat string
+	unit statUnit
+	out  string
 }{
-	{"hits", countFormat, 1, false, "brubeck.stats_d.appname.hits:1|c"},
-	{"qps", timeFormat, 20.004, true, "brubeck.stats_d.timers.appname.qps:20.00|ms"},
+	{"hits", unitCount, "brubeck.stats_d.appname.hits"},
+	{"qps", unitTime, "brubeck.stats_d.timers.appname.qps"},
+	{"active", unitGauge, "brubeck.stats_d.gauges.appname.active"},
+	{"users", unitSet, "brubeck.stats_d.sets.appname.users"},
+	{"req_size", unitHistogram, "brubeck.stats_d.histograms.appname.req_size"},
+	{"throughput", unitMeter, "brubeck.stats_d.meters.appname.throughput"},
 }
 
 var sampledCountsTests = []struct {
@@ -30,7 +31,13 @@ var sampledCountsTests = []struct {
 }
 
 func disabledClient() *Client {
-	return NewClient(testAppname, "statsd.i.wish.com", true)
+	client, _ := NewClient(testAppname, "statsd.i.wish.com", true)
+	return client
+}
+
+func taggedClient(tags ...Tag) *Client {
+	client, _ := NewClient(testAppname, "statsd.i.wish.com", true, WithTags(), WithBaseTags(tags...))
+	return client
 }
 
 func TestPrefixT(t *testing.T) {
@@ -40,11 +47,10 @@ func TestPrefixT(t *testing.T) {
 	}
 }
 
-func TestStatParser(t *testing.T) {
+func TestNamespacedStat(t *testing.T) {
 	client := disabledClient()
-
-	for _, tt := range formatTests {
-		s := client.formatStat(tt.stat, tt.format, tt.value, tt.timed)
+	for _, tt := range namespacedStatTests {
+		s := client.namespacedStat(tt.stat, tt.unit)
 		if s != tt.out {
 			t.Errorf("%s != %s", s, tt.out)
 		}
@@ -67,14 +73,23 @@ func TestSampledCounts(t *testing.T) {
 	}
 }
 
-func TestFormatStat(t *testing.T) {
+func TestMergeTagsDisabledByDefault(t *testing.T) {
 	client := disabledClient()
-	formatted := client.formatStat("asdf", timeFormat, float32(2), true)
-	if !strings.Contains(formatted, timerNamespacePrefix) {
-		t.Errorf("Timer stat does not contain timer namespace prefix")
+	if tags := client.mergeTags([]Tag{{"route", "/login"}}); tags != nil {
+		t.Errorf("expected no tags without WithTags, got %v", tags)
 	}
-	formatted = client.formatStat("asdf", countFormat, float32(2), false)
-	if !strings.Contains(formatted, namespacePrefix) {
-		t.Errorf("Count stat does not contain count namespace prefix")
+}
+
+func TestMergeTags(t *testing.T) {
+	client := taggedClient(Tag{"node", "web1"})
+	tags := client.mergeTags([]Tag{{"route", "/login"}})
+	want := []Tag{{"node", "web1"}, {"route", "/login"}}
+	if len(tags) != len(want) || tags[0] != want[0] || tags[1] != want[1] {
+		t.Errorf("unexpected merged tags: %v", tags)
 	}
 }
+
+func TestIncrTagged(t *testing.T) {
+	client := taggedClient(Tag{"node", "web1"})
+	client.IncrTagged("hits", 1, Tag{"route", "/login"})
+}
@@ -0,0 +1,160 @@
+package statsd
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const statsdPort = 8125
+const unixgramScheme = "unixgram://"
+
+const baseBackoff = 100 * time.Millisecond
+const maxBackoff = 30 * time.Second
+
+// ErrorHandler is called with dial and write failures so callers can log
+// or alert on them instead of them being silently dropped.
+type ErrorHandler func(error)
+
+// WithErrorHandler registers a callback invoked whenever the client fails
+// to dial or write to its sink.
+func WithErrorHandler(h ErrorHandler) Option {
+	return func(c *Client) {
+		c.errorHandler = h
+	}
+}
+
+// udpSink is the default Sink: it speaks the statsd/DogStatsD UDP wire
+// format, e.g. "brubeck.stats_d.appname.hits:1|c". It reconnects lazily
+// (on the next write, not in the background) with jittered exponential
+// backoff when the connection is lost, and re-resolves its address on
+// every reconnect so DNS changes for the statsd host are picked up.
+type udpSink struct {
+	network string // "udp" or "unixgram"
+	address string
+	onError ErrorHandler
+
+	mu         sync.Mutex
+	nc         net.Conn
+	backoff    time.Duration
+	nextDialAt time.Time
+
+	// writeFunc sends a single formatted stat. It defaults to write, but
+	// NewBufferedClient replaces it to accumulate stats into a buffer
+	// instead, flushing through write in batches.
+	writeFunc func([]byte)
+}
+
+// newUDPSink dials host, which is either a bare hostname (UDP to the
+// standard Brubeck/statsd port) or a "unixgram:///path/to/socket" URL for
+// unix datagram sockets. It only returns an error for a malformed host,
+// e.g. an empty hostname or a "unixgram://" URL with no socket path. A
+// failure to dial a well-formed address is reported via onError and left
+// to the lazy-reconnect path in write, rather than failing the client
+// outright, since the statsd relay may simply not be up yet.
+func newUDPSink(host string, onError ErrorHandler) (*udpSink, error) {
+	network, address, err := parseSinkAddress(host)
+	if err != nil {
+		return nil, err
+	}
+	s := &udpSink{network: network, address: address, onError: onError}
+	s.writeFunc = s.write
+
+	s.mu.Lock()
+	if err := s.dialLocked(); err != nil {
+		s.reportLocked(err)
+		s.scheduleBackoffLocked()
+	}
+	s.mu.Unlock()
+
+	return s, nil
+}
+
+func parseSinkAddress(host string) (network string, address string, err error) {
+	if strings.HasPrefix(host, unixgramScheme) {
+		path := strings.TrimPrefix(host, unixgramScheme)
+		if path == "" {
+			return "", "", fmt.Errorf("statsd: %q has no socket path", host)
+		}
+		return "unixgram", path, nil
+	}
+	if host == "" {
+		return "", "", fmt.Errorf("statsd: empty host")
+	}
+	return "udp", host + ":" + strconv.Itoa(statsdPort), nil
+}
+
+// dialLocked dials a fresh connection, re-resolving the address so DNS
+// changes for the statsd relay are picked up on every reconnect.
+func (s *udpSink) dialLocked() error {
+	conn, err := net.DialTimeout(s.network, s.address, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	s.nc = conn
+	return nil
+}
+
+func (s *udpSink) reportLocked(err error) {
+	if s.onError != nil {
+		s.onError(err)
+	}
+}
+
+// scheduleBackoffLocked grows the reconnect backoff exponentially, capped
+// at maxBackoff, and jitters it so a fleet of clients losing their relay
+// at once doesn't reconnect in lockstep.
+func (s *udpSink) scheduleBackoffLocked() {
+	if s.backoff == 0 {
+		s.backoff = baseBackoff
+	} else {
+		s.backoff *= 2
+		if s.backoff > maxBackoff {
+			s.backoff = maxBackoff
+		}
+	}
+	jitter := time.Duration(rand.Int63n(int64(s.backoff)))
+	s.nextDialAt = time.Now().Add(s.backoff/2 + jitter/2)
+}
+
+// write sends b, lazily reconnecting (respecting the current backoff
+// window) if the connection was previously lost.
+func (s *udpSink) write(b []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.nc == nil {
+		if time.Now().Before(s.nextDialAt) {
+			return
+		}
+		if err := s.dialLocked(); err != nil {
+			s.reportLocked(err)
+			s.scheduleBackoffLocked()
+			return
+		}
+		s.backoff = 0
+	}
+
+	if _, err := s.nc.Write(b); err != nil {
+		s.reportLocked(err)
+		s.nc.Close()
+		s.nc = nil
+		s.scheduleBackoffLocked()
+	}
+}
+
+// close closes the underlying connection, if any.
+func (s *udpSink) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.nc == nil {
+		return nil
+	}
+	err := s.nc.Close()
+	s.nc = nil
+	return err
+}
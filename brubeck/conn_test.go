@@ -0,0 +1,99 @@
+package statsd
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// failingConn is a net.Conn whose Write always fails, for exercising the
+// reconnect-on-write-error path without a live socket.
+type failingConn struct {
+	net.Conn
+}
+
+func (f *failingConn) Write(b []byte) (int, error) {
+	return 0, errors.New("write: connection refused")
+}
+
+func (f *failingConn) Close() error {
+	return nil
+}
+
+func TestParseSinkAddress(t *testing.T) {
+	network, address, err := parseSinkAddress("statsd.internal")
+	if err != nil || network != "udp" || address != "statsd.internal:8125" {
+		t.Errorf("got %s %s %v, want udp statsd.internal:8125 <nil>", network, address, err)
+	}
+
+	network, address, err = parseSinkAddress("unixgram:///var/run/statsd.sock")
+	if err != nil || network != "unixgram" || address != "/var/run/statsd.sock" {
+		t.Errorf("got %s %s %v, want unixgram /var/run/statsd.sock <nil>", network, address, err)
+	}
+}
+
+func TestParseSinkAddressRejectsMalformedHost(t *testing.T) {
+	if _, _, err := parseSinkAddress(""); err == nil {
+		t.Error("expected an error for an empty host")
+	}
+	if _, _, err := parseSinkAddress("unixgram://"); err == nil {
+		t.Error("expected an error for a unixgram:// URL with no socket path")
+	}
+}
+
+func TestNewClientRejectsMalformedHost(t *testing.T) {
+	if _, err := NewClient(testAppname, "unixgram://", false); err == nil {
+		t.Error("expected NewClient to surface the malformed host error")
+	}
+}
+
+func TestUDPSinkWriteDropsConnectionAndBacksOffAfterFailure(t *testing.T) {
+	s := &udpSink{network: "udp", address: "127.0.0.1:1", nc: &failingConn{}}
+
+	var reported error
+	s.onError = func(err error) { reported = err }
+
+	s.write([]byte("stat:1|c"))
+
+	if reported == nil {
+		t.Error("expected the write failure to be reported")
+	}
+	if s.nc != nil {
+		t.Error("expected the broken connection to be dropped")
+	}
+	if s.backoff == 0 {
+		t.Error("expected a backoff to be scheduled after a write failure")
+	}
+}
+
+func TestUDPSinkWriteSkipsRedialDuringBackoff(t *testing.T) {
+	s := &udpSink{network: "udp", address: "127.0.0.1:1"}
+	dialAttempts := 0
+	s.onError = func(error) { dialAttempts++ }
+	s.nextDialAt = time.Now().Add(time.Hour)
+
+	s.write([]byte("stat:1|c"))
+
+	if dialAttempts != 0 {
+		t.Error("expected write to skip redialing while still inside the backoff window")
+	}
+	if s.nc != nil {
+		t.Error("expected no new connection to be dialed while still inside the backoff window")
+	}
+}
+
+func TestScheduleBackoffGrowsAndCaps(t *testing.T) {
+	s := &udpSink{}
+	var last time.Duration
+	for i := 0; i < 20; i++ {
+		s.scheduleBackoffLocked()
+		if s.backoff < last {
+			t.Fatalf("backoff shrank: %v -> %v", last, s.backoff)
+		}
+		last = s.backoff
+	}
+	if s.backoff > maxBackoff {
+		t.Errorf("backoff %v exceeded cap %v", s.backoff, maxBackoff)
+	}
+}
@@ -0,0 +1,54 @@
+package statsd
+
+// FanoutSink broadcasts every emission to a fixed set of child Sinks, e.g.
+// to send stats to both a UDP statsd server and a MemorySink used by tests.
+type FanoutSink struct {
+	sinks []Sink
+}
+
+// NewFanoutSink creates a FanoutSink broadcasting to the given children.
+func NewFanoutSink(sinks ...Sink) *FanoutSink {
+	return &FanoutSink{sinks: sinks}
+}
+
+func (f *FanoutSink) EmitCounter(stat string, value int64, tags []Tag) {
+	for _, s := range f.sinks {
+		s.EmitCounter(stat, value, tags)
+	}
+}
+
+func (f *FanoutSink) EmitTimer(stat string, value float32, tags []Tag) {
+	for _, s := range f.sinks {
+		s.EmitTimer(stat, value, tags)
+	}
+}
+
+func (f *FanoutSink) EmitGauge(stat string, value float64, tags []Tag) {
+	for _, s := range f.sinks {
+		s.EmitGauge(stat, value, tags)
+	}
+}
+
+func (f *FanoutSink) EmitGaugeDelta(stat string, delta float64, tags []Tag) {
+	for _, s := range f.sinks {
+		s.EmitGaugeDelta(stat, delta, tags)
+	}
+}
+
+func (f *FanoutSink) EmitSet(stat string, value string, tags []Tag) {
+	for _, s := range f.sinks {
+		s.EmitSet(stat, value, tags)
+	}
+}
+
+func (f *FanoutSink) EmitHistogram(stat string, value float64, tags []Tag) {
+	for _, s := range f.sinks {
+		s.EmitHistogram(stat, value, tags)
+	}
+}
+
+func (f *FanoutSink) EmitMeter(stat string, value float64, tags []Tag) {
+	for _, s := range f.sinks {
+		s.EmitMeter(stat, value, tags)
+	}
+}
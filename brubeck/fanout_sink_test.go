@@ -0,0 +1,18 @@
+package statsd
+
+import "testing"
+
+func TestFanoutSinkBroadcasts(t *testing.T) {
+	a := NewMemorySink(10)
+	b := NewMemorySink(10)
+	fanout := NewFanoutSink(a, b)
+
+	fanout.EmitCounter("appname.hits", 1, nil)
+
+	if _, ok := a.Stats("appname.hits"); !ok {
+		t.Error("expected first sink to receive the emission")
+	}
+	if _, ok := b.Stats("appname.hits"); !ok {
+		t.Error("expected second sink to receive the emission")
+	}
+}
@@ -0,0 +1,73 @@
+package statsd
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// GraphiteSink is a Sink that speaks the Graphite plaintext protocol over
+// TCP, formatting every emission as "path value timestamp\n". Graphite has
+// no notion of statsd units or tags, so those are dropped; EmitSet records
+// a presence marker of 1 rather than a true unique count, since uniqueness
+// is computed server-side by statsd/DogStatsD, not Graphite.
+type GraphiteSink struct {
+	mu sync.Mutex
+	nc net.Conn
+}
+
+// NewGraphiteSink dials a Graphite carbon plaintext listener at addr
+// (host:port).
+func NewGraphiteSink(addr string) (*GraphiteSink, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &GraphiteSink{nc: conn}, nil
+}
+
+// Close closes the underlying TCP connection.
+func (g *GraphiteSink) Close() error {
+	return g.nc.Close()
+}
+
+func (g *GraphiteSink) emit(path string, value float64) {
+	line := formatGraphiteLine(path, value, time.Now().Unix())
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.nc.Write([]byte(line))
+}
+
+// formatGraphiteLine renders a single Graphite plaintext sample.
+func formatGraphiteLine(path string, value float64, timestamp int64) string {
+	return fmt.Sprintf("%s %v %d\n", path, value, timestamp)
+}
+
+func (g *GraphiteSink) EmitCounter(stat string, value int64, tags []Tag) {
+	g.emit(stat, float64(value))
+}
+
+func (g *GraphiteSink) EmitTimer(stat string, value float32, tags []Tag) {
+	g.emit(stat, float64(value))
+}
+
+func (g *GraphiteSink) EmitGauge(stat string, value float64, tags []Tag) {
+	g.emit(stat, value)
+}
+
+func (g *GraphiteSink) EmitGaugeDelta(stat string, delta float64, tags []Tag) {
+	g.emit(stat, delta)
+}
+
+func (g *GraphiteSink) EmitSet(stat string, value string, tags []Tag) {
+	g.emit(stat, 1)
+}
+
+func (g *GraphiteSink) EmitHistogram(stat string, value float64, tags []Tag) {
+	g.emit(stat, value)
+}
+
+func (g *GraphiteSink) EmitMeter(stat string, value float64, tags []Tag) {
+	g.emit(stat, value)
+}
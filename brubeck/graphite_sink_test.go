@@ -0,0 +1,72 @@
+package statsd
+
+import (
+	"bufio"
+	"net"
+	"testing"
+)
+
+// newTestGraphiteSink dials sink against a local TCP listener so
+// GraphiteSink's wire format can be asserted on without a live carbon
+// server.
+func newTestGraphiteSink(t *testing.T) (*GraphiteSink, *bufio.Reader) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start test listener: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	acceptedCh := make(chan net.Conn, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			acceptedCh <- conn
+		}
+	}()
+
+	sink, err := NewGraphiteSink(ln.Addr().String())
+	if err != nil {
+		t.Fatalf("NewGraphiteSink returned an error: %v", err)
+	}
+	t.Cleanup(func() { sink.Close() })
+
+	conn := <-acceptedCh
+	t.Cleanup(func() { conn.Close() })
+	return sink, bufio.NewReader(conn)
+}
+
+func TestFormatGraphiteLine(t *testing.T) {
+	got := formatGraphiteLine("appname.hits", 3.5, 1700000000)
+	want := "appname.hits 3.5 1700000000\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestGraphiteSinkEmitCounter(t *testing.T) {
+	sink, r := newTestGraphiteSink(t)
+	sink.EmitCounter("appname.hits", 1, nil)
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatalf("failed to read emitted line: %v", err)
+	}
+	if want := "appname.hits 1 "; line[:len(want)] != want {
+		t.Errorf("got %q, want prefix %q", line, want)
+	}
+}
+
+func TestGraphiteSinkEmitSetRecordsPresenceMarker(t *testing.T) {
+	sink, r := newTestGraphiteSink(t)
+	sink.EmitSet("appname.users", "user-123", nil)
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatalf("failed to read emitted line: %v", err)
+	}
+	if want := "appname.users 1 "; line[:len(want)] != want {
+		t.Errorf("expected EmitSet to record a presence marker of 1, got %q", line)
+	}
+}
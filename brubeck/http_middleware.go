@@ -0,0 +1,69 @@
+package statsd
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// statusRecorder wraps a ResponseWriter to capture the status code the
+// handler wrote, defaulting to 200 if WriteHeader is never called.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// HTTPMiddleware wraps next, emitting a request-count stat and a
+// millisecond latency timer per method + normalized route, plus a
+// status-code counter, e.g.:
+//
+//	http.request.get.users.id.count
+//	http.request.get.users.id.latency
+//	http.request.get.users.id.status.200
+func (c *Client) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		base := fmt.Sprintf("http.request.%s.%s", strings.ToLower(r.Method), normalizeRoute(r.URL.Path))
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		timer := c.NewTimer(base + ".latency")
+
+		c.Incr(base + ".count")
+		next.ServeHTTP(rec, r)
+		timer.Stop()
+		c.Incr(fmt.Sprintf("%s.status.%d", base, rec.status))
+	})
+}
+
+// normalizeRoute collapses numeric path segments (e.g. resource IDs) down
+// to "id" so per-request paths fold into a single stat instead of one stat
+// per distinct ID, e.g. "/users/123/orders" becomes "users.id.orders".
+func normalizeRoute(path string) string {
+	var segments []string
+	for _, seg := range strings.Split(path, "/") {
+		if seg == "" {
+			continue
+		}
+		if isNumeric(seg) {
+			seg = "id"
+		}
+		segments = append(segments, seg)
+	}
+	if len(segments) == 0 {
+		return "root"
+	}
+	return strings.Join(segments, ".")
+}
+
+func isNumeric(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package statsd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPMiddlewareRecordsRequestsAndStatus(t *testing.T) {
+	client, sink := memoryClient()
+
+	handler := client.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/users/123", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	base := "brubeck.stats_d.appname.http.request.post.users.id"
+	if _, ok := sink.Stats(base + ".count"); !ok {
+		t.Error("expected a request-count stat")
+	}
+	if _, ok := sink.Stats(base + ".status.201"); !ok {
+		t.Error("expected a status-code stat for 201")
+	}
+	if _, ok := sink.Stats("brubeck.stats_d.timers.appname.http.request.post.users.id.latency"); !ok {
+		t.Error("expected a latency timing")
+	}
+}
+
+func TestHTTPMiddlewareDefaultsStatusToOK(t *testing.T) {
+	client, sink := memoryClient()
+
+	handler := client.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if _, ok := sink.Stats("brubeck.stats_d.appname.http.request.get.root.status.200"); !ok {
+		t.Error("expected a 200 status stat when the handler never calls WriteHeader")
+	}
+}
+
+func TestNormalizeRoute(t *testing.T) {
+	cases := map[string]string{
+		"/":                  "root",
+		"/users":             "users",
+		"/users/123":         "users.id",
+		"/users/123/orders":  "users.id.orders",
+		"/users/123/orders/": "users.id.orders",
+	}
+	for path, want := range cases {
+		if got := normalizeRoute(path); got != want {
+			t.Errorf("normalizeRoute(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
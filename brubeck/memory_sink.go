@@ -0,0 +1,192 @@
+package statsd
+
+import (
+	"sort"
+	"sync"
+)
+
+// MetricStats summarizes the samples currently held for one metric in a
+// MemorySink.
+type MetricStats struct {
+	Count int
+	Min   float64
+	Max   float64
+	Mean  float64
+	P50   float64
+	P90   float64
+	P99   float64
+}
+
+// ringBuffer retains up to capacity float64 samples, discarding the oldest
+// once full.
+type ringBuffer struct {
+	mu     sync.Mutex
+	data   []float64
+	next   int
+	filled bool
+}
+
+func newRingBuffer(capacity int) *ringBuffer {
+	return &ringBuffer{data: make([]float64, capacity)}
+}
+
+func (r *ringBuffer) add(value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.data[r.next] = value
+	r.next++
+	if r.next == len(r.data) {
+		r.next = 0
+		r.filled = true
+	}
+}
+
+// samples returns the retained samples, oldest first.
+func (r *ringBuffer) samples() []float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.filled {
+		out := make([]float64, r.next)
+		copy(out, r.data[:r.next])
+		return out
+	}
+	out := make([]float64, len(r.data))
+	copy(out, r.data[r.next:])
+	copy(out[len(r.data)-r.next:], r.data[:r.next])
+	return out
+}
+
+// MemorySink is a Sink that retains recent samples per metric in ring
+// buffers, with min/max/mean/p50/p90/p99 computed on demand. It's useful
+// for tests asserting on what a Client emitted, and as the backing store
+// for a "/debug/metrics" HTTP handler.
+type MemorySink struct {
+	capacity int
+
+	mu      sync.Mutex
+	buffers map[string]*ringBuffer
+	sets    map[string]map[string]struct{}
+}
+
+// defaultMemorySinkCapacity is used in place of a capacity <= 0 passed to
+// NewMemorySink.
+const defaultMemorySinkCapacity = 100
+
+// NewMemorySink creates a MemorySink retaining up to capacity samples per
+// metric name. A capacity of 0 or less uses defaultMemorySinkCapacity.
+func NewMemorySink(capacity int) *MemorySink {
+	if capacity <= 0 {
+		capacity = defaultMemorySinkCapacity
+	}
+	return &MemorySink{
+		capacity: capacity,
+		buffers:  make(map[string]*ringBuffer),
+		sets:     make(map[string]map[string]struct{}),
+	}
+}
+
+func (m *MemorySink) bufferFor(stat string) *ringBuffer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, ok := m.buffers[stat]
+	if !ok {
+		b = newRingBuffer(m.capacity)
+		m.buffers[stat] = b
+	}
+	return b
+}
+
+func (m *MemorySink) EmitCounter(stat string, value int64, tags []Tag) {
+	m.bufferFor(stat).add(float64(value))
+}
+
+func (m *MemorySink) EmitTimer(stat string, value float32, tags []Tag) {
+	m.bufferFor(stat).add(float64(value))
+}
+
+func (m *MemorySink) EmitGauge(stat string, value float64, tags []Tag) {
+	m.bufferFor(stat).add(value)
+}
+
+func (m *MemorySink) EmitGaugeDelta(stat string, delta float64, tags []Tag) {
+	m.bufferFor(stat).add(delta)
+}
+
+func (m *MemorySink) EmitHistogram(stat string, value float64, tags []Tag) {
+	m.bufferFor(stat).add(value)
+}
+
+func (m *MemorySink) EmitMeter(stat string, value float64, tags []Tag) {
+	m.bufferFor(stat).add(value)
+}
+
+func (m *MemorySink) EmitSet(stat string, value string, tags []Tag) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	set, ok := m.sets[stat]
+	if !ok {
+		set = make(map[string]struct{})
+		m.sets[stat] = set
+	}
+	set[value] = struct{}{}
+}
+
+// Stats computes the current min/max/mean/percentiles for a metric. The
+// second return value is false if nothing has been recorded for stat yet.
+func (m *MemorySink) Stats(stat string) (MetricStats, bool) {
+	m.mu.Lock()
+	b, ok := m.buffers[stat]
+	m.mu.Unlock()
+	if !ok {
+		return MetricStats{}, false
+	}
+
+	samples := b.samples()
+	if len(samples) == 0 {
+		return MetricStats{}, false
+	}
+	return computeStats(samples), true
+}
+
+// UniqueCount returns the number of distinct values recorded via EmitSet
+// for stat.
+func (m *MemorySink) UniqueCount(stat string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sets[stat])
+}
+
+func computeStats(samples []float64) MetricStats {
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+
+	return MetricStats{
+		Count: len(sorted),
+		Min:   sorted[0],
+		Max:   sorted[len(sorted)-1],
+		Mean:  sum / float64(len(sorted)),
+		P50:   percentile(sorted, 0.50),
+		P90:   percentile(sorted, 0.90),
+		P99:   percentile(sorted, 0.99),
+	}
+}
+
+// percentile returns the value at rank p (0..1) of an already-sorted slice:
+// the sample at index int(p*(len-1)), truncated toward zero, with no
+// interpolation between neighbouring samples.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	idx := int(p * float64(len(sorted)-1))
+	return sorted[idx]
+}
@@ -0,0 +1,71 @@
+package statsd
+
+import "testing"
+
+func TestMemorySinkStats(t *testing.T) {
+	m := NewMemorySink(100)
+	m.EmitTimer("appname.qps", 10, nil)
+	m.EmitTimer("appname.qps", 20, nil)
+	m.EmitTimer("appname.qps", 30, nil)
+
+	stats, ok := m.Stats("appname.qps")
+	if !ok {
+		t.Fatal("expected stats to be present")
+	}
+	if stats.Count != 3 {
+		t.Errorf("Count = %d, want 3", stats.Count)
+	}
+	if stats.Min != 10 || stats.Max != 30 {
+		t.Errorf("Min/Max = %v/%v, want 10/30", stats.Min, stats.Max)
+	}
+	if stats.Mean != 20 {
+		t.Errorf("Mean = %v, want 20", stats.Mean)
+	}
+}
+
+func TestMemorySinkStatsMissing(t *testing.T) {
+	m := NewMemorySink(100)
+	if _, ok := m.Stats("nope"); ok {
+		t.Error("expected no stats for a metric that was never emitted")
+	}
+}
+
+func TestMemorySinkRingBufferEviction(t *testing.T) {
+	m := NewMemorySink(2)
+	m.EmitGauge("appname.active", 1, nil)
+	m.EmitGauge("appname.active", 2, nil)
+	m.EmitGauge("appname.active", 3, nil)
+
+	stats, _ := m.Stats("appname.active")
+	if stats.Count != 2 {
+		t.Errorf("Count = %d, want 2 after eviction", stats.Count)
+	}
+	if stats.Min != 2 || stats.Max != 3 {
+		t.Errorf("expected oldest sample evicted, got Min=%v Max=%v", stats.Min, stats.Max)
+	}
+}
+
+func TestMemorySinkDefaultsNonPositiveCapacity(t *testing.T) {
+	m := NewMemorySink(0)
+	m.EmitCounter("appname.hits", 1, nil)
+	if _, ok := m.Stats("appname.hits"); !ok {
+		t.Fatal("expected a zero capacity to default rather than panic")
+	}
+
+	m = NewMemorySink(-5)
+	m.EmitCounter("appname.hits", 1, nil)
+	if _, ok := m.Stats("appname.hits"); !ok {
+		t.Fatal("expected a negative capacity to default rather than panic")
+	}
+}
+
+func TestMemorySinkUniqueCount(t *testing.T) {
+	m := NewMemorySink(100)
+	m.EmitSet("appname.users", "alice", nil)
+	m.EmitSet("appname.users", "bob", nil)
+	m.EmitSet("appname.users", "alice", nil)
+
+	if got := m.UniqueCount("appname.users"); got != 2 {
+		t.Errorf("UniqueCount = %d, want 2", got)
+	}
+}
@@ -0,0 +1,77 @@
+package statsd
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Sink is the emission backend a Client writes metrics through. Client
+// itself only handles naming, namespacing, and tag policy; everything about
+// how (and where) a stat actually gets delivered lives behind this
+// interface. See newUDPSink (the default), NewMemorySink, NewFanoutSink,
+// and NewGraphiteSink.
+type Sink interface {
+	EmitCounter(stat string, value int64, tags []Tag)
+	EmitTimer(stat string, value float32, tags []Tag)
+	EmitGauge(stat string, value float64, tags []Tag)
+	EmitGaugeDelta(stat string, delta float64, tags []Tag)
+	EmitSet(stat string, value string, tags []Tag)
+	EmitHistogram(stat string, value float64, tags []Tag)
+	EmitMeter(stat string, value float64, tags []Tag)
+}
+
+// tagReplacer escapes the reserved DogStatsD tag characters ('|', ',', ':')
+// out of tag keys and values so a malformed tag can't corrupt the wire
+// format or bleed into an adjacent tag.
+var tagReplacer = strings.NewReplacer("|", "_", ",", "_", ":", "_")
+
+// tagSuffix renders tags as the DogStatsD "|#k1:v1,k2:v2" suffix, or ""
+// when there are no tags to send.
+func tagSuffix(tags []Tag) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	parts := make([]string, len(tags))
+	for i, tag := range tags {
+		parts[i] = tagReplacer.Replace(tag.Key) + ":" + tagReplacer.Replace(tag.Value)
+	}
+	return "|#" + strings.Join(parts, ",")
+}
+
+func (s *udpSink) EmitCounter(stat string, value int64, tags []Tag) {
+	s.writeFunc([]byte(fmt.Sprintf("%s:%d|c%s", stat, value, tagSuffix(tags))))
+}
+
+func (s *udpSink) EmitTimer(stat string, value float32, tags []Tag) {
+	s.writeFunc([]byte(fmt.Sprintf("%s:%.2f|ms%s", stat, value, tagSuffix(tags))))
+}
+
+func (s *udpSink) EmitGauge(stat string, value float64, tags []Tag) {
+	s.writeFunc([]byte(fmt.Sprintf("%s:%v|g%s", stat, value, tagSuffix(tags))))
+}
+
+// EmitGaugeDelta adjusts a gauge relative to its current value. Per the
+// DogStatsD gauge extension, this requires a leading sign on the wire, so
+// the sign and magnitude are formatted separately: math.Signbit (rather
+// than delta >= 0) decides the sign, since delta >= 0 is true for negative
+// zero and would otherwise double up with %v's own "-0" into "+-0".
+func (s *udpSink) EmitGaugeDelta(stat string, delta float64, tags []Tag) {
+	sign := "+"
+	if math.Signbit(delta) {
+		sign = "-"
+	}
+	s.writeFunc([]byte(fmt.Sprintf("%s:%s%v|g%s", stat, sign, math.Abs(delta), tagSuffix(tags))))
+}
+
+func (s *udpSink) EmitSet(stat string, value string, tags []Tag) {
+	s.writeFunc([]byte(fmt.Sprintf("%s:%s|s%s", stat, value, tagSuffix(tags))))
+}
+
+func (s *udpSink) EmitHistogram(stat string, value float64, tags []Tag) {
+	s.writeFunc([]byte(fmt.Sprintf("%s:%v|h%s", stat, value, tagSuffix(tags))))
+}
+
+func (s *udpSink) EmitMeter(stat string, value float64, tags []Tag) {
+	s.writeFunc([]byte(fmt.Sprintf("%s:%v|m%s", stat, value, tagSuffix(tags))))
+}
@@ -0,0 +1,114 @@
+package statsd
+
+import (
+	"math"
+	"testing"
+)
+
+func newTestUDPSink() (*udpSink, *fakeConn) {
+	conn := &fakeConn{}
+	s := &udpSink{nc: conn}
+	s.writeFunc = s.write
+	return s, conn
+}
+
+func lastWrite(conn *fakeConn) string {
+	writes := conn.Writes()
+	if len(writes) == 0 {
+		return ""
+	}
+	return string(writes[len(writes)-1])
+}
+
+func TestUDPSinkEmitCounter(t *testing.T) {
+	s, conn := newTestUDPSink()
+	s.EmitCounter("brubeck.stats_d.appname.hits", 1, nil)
+	if got, want := lastWrite(conn), "brubeck.stats_d.appname.hits:1|c"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestUDPSinkEmitTimer(t *testing.T) {
+	s, conn := newTestUDPSink()
+	s.EmitTimer("brubeck.stats_d.timers.appname.qps", 20.004, nil)
+	if got, want := lastWrite(conn), "brubeck.stats_d.timers.appname.qps:20.00|ms"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestUDPSinkEmitGauge(t *testing.T) {
+	s, conn := newTestUDPSink()
+	s.EmitGauge("brubeck.stats_d.gauges.appname.active", 3.5, nil)
+	if got, want := lastWrite(conn), "brubeck.stats_d.gauges.appname.active:3.5|g"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestUDPSinkEmitGaugeDeltaSign(t *testing.T) {
+	s, conn := newTestUDPSink()
+	s.EmitGaugeDelta("asdf", 5, nil)
+	if got, want := lastWrite(conn), "asdf:+5|g"; got != want {
+		t.Errorf("expected leading + for non-negative gauge delta, got %q want %q", got, want)
+	}
+	s.EmitGaugeDelta("asdf", -5, nil)
+	if got, want := lastWrite(conn), "asdf:-5|g"; got != want {
+		t.Errorf("expected leading - for negative gauge delta, got %q want %q", got, want)
+	}
+}
+
+func TestUDPSinkEmitGaugeDeltaNegativeZero(t *testing.T) {
+	s, conn := newTestUDPSink()
+	s.EmitGaugeDelta("asdf", math.Copysign(0, -1), nil)
+	if got, want := lastWrite(conn), "asdf:-0|g"; got != want {
+		t.Errorf("expected a single leading - for negative zero, got %q want %q", got, want)
+	}
+	s.EmitGaugeDelta("asdf", 0, nil)
+	if got, want := lastWrite(conn), "asdf:+0|g"; got != want {
+		t.Errorf("expected a leading + for positive zero, got %q want %q", got, want)
+	}
+}
+
+func TestUDPSinkEmitSet(t *testing.T) {
+	s, conn := newTestUDPSink()
+	s.EmitSet("brubeck.stats_d.sets.appname.users", "user-123", nil)
+	if got, want := lastWrite(conn), "brubeck.stats_d.sets.appname.users:user-123|s"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestUDPSinkEmitHistogram(t *testing.T) {
+	s, conn := newTestUDPSink()
+	s.EmitHistogram("brubeck.stats_d.histograms.appname.req_size", 512, nil)
+	if got, want := lastWrite(conn), "brubeck.stats_d.histograms.appname.req_size:512|h"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestUDPSinkEmitMeter(t *testing.T) {
+	s, conn := newTestUDPSink()
+	s.EmitMeter("brubeck.stats_d.meters.appname.throughput", 42, nil)
+	if got, want := lastWrite(conn), "brubeck.stats_d.meters.appname.throughput:42|m"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestUDPSinkEmitWithTags(t *testing.T) {
+	s, conn := newTestUDPSink()
+	s.EmitCounter("appname.hits", 1, []Tag{{"route", "/login"}, {"status", "200"}})
+	if got, want := lastWrite(conn), "appname.hits:1|c|#route:/login,status:200"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTagSuffixEscapesReservedChars(t *testing.T) {
+	got := tagSuffix([]Tag{{"route", "a|b,c:d"}})
+	if want := "|#route:a_b_c_d"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTagSuffixEmpty(t *testing.T) {
+	if got := tagSuffix(nil); got != "" {
+		t.Errorf("expected empty suffix for no tags, got %q", got)
+	}
+}
@@ -0,0 +1,32 @@
+package statsd
+
+import "time"
+
+// Timer measures elapsed time and reports it to statsd when stopped.
+// Obtain one from Client.NewTimer.
+type Timer struct {
+	client *Client
+	stat   string
+	start  time.Time
+}
+
+// NewTimer starts a Timer for stat. Typical usage:
+//
+//	defer client.NewTimer("db.query").Stop()
+func (c *Client) NewTimer(stat string) *Timer {
+	return &Timer{client: c, stat: stat, start: time.Now()}
+}
+
+// Stop records the elapsed time since NewTimer as a millisecond timing.
+func (t *Timer) Stop() {
+	elapsedMs := float32(time.Since(t.start)) / float32(time.Millisecond)
+	t.client.Time(t.stat, elapsedMs)
+}
+
+// TimeFunc runs fn and reports its elapsed wall-clock time as a millisecond
+// timing under stat.
+func (c *Client) TimeFunc(stat string, fn func()) {
+	timer := c.NewTimer(stat)
+	fn()
+	timer.Stop()
+}
@@ -0,0 +1,45 @@
+package statsd
+
+import (
+	"testing"
+	"time"
+)
+
+func memoryClient() (*Client, *MemorySink) {
+	sink := NewMemorySink(100)
+	client, _ := NewClient(testAppname, "", false, WithSink(sink))
+	return client, sink
+}
+
+func TestTimerStopRecordsElapsed(t *testing.T) {
+	client, sink := memoryClient()
+
+	timer := client.NewTimer("db.query")
+	time.Sleep(5 * time.Millisecond)
+	timer.Stop()
+
+	stats, ok := sink.Stats("brubeck.stats_d.timers.appname.db.query")
+	if !ok {
+		t.Fatal("expected a timing to have been recorded")
+	}
+	if stats.Max < 5 {
+		t.Errorf("expected recorded elapsed time >= 5ms, got %v", stats.Max)
+	}
+}
+
+func TestTimeFunc(t *testing.T) {
+	client, sink := memoryClient()
+
+	ran := false
+	client.TimeFunc("db.query", func() {
+		ran = true
+		time.Sleep(time.Millisecond)
+	})
+
+	if !ran {
+		t.Error("expected TimeFunc to run fn")
+	}
+	if _, ok := sink.Stats("brubeck.stats_d.timers.appname.db.query"); !ok {
+		t.Error("expected TimeFunc to record a timing")
+	}
+}